@@ -0,0 +1,280 @@
+//go:build linux
+
+// Package cache provides a persistent on-disk cache of directory listings
+// for repeated recursive walks of large trees.
+//
+// cache is Linux-only: it reads syscall.Stat_t's Ctim field directly for
+// change detection and calls unixdirents.Dirents with its Linux fd-based
+// signature, neither of which unixdirents abstracts across GOOS the way its
+// own Dirents iterator does.
+package cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hnakamur/readdiriter/unixdirents"
+)
+
+// formatVersion is written to the cache file header so that future schema
+// changes can be detected instead of silently corrupting old caches.
+const formatVersion = 1
+
+// Entry is one cached directory entry.
+type Entry struct {
+	// Name is the entry's base name within its directory.
+	Name string
+	// Ino is the entry's inode number.
+	Ino uint64
+	// Type is the entry's file type bits, as returned by fs.DirEntry.Type.
+	Type fs.FileMode
+}
+
+// dirRecord is the cached listing for one directory, along with the
+// directory's own mtime and ctime at the time it was read. A directory's
+// cached listing is reused only while both are unchanged.
+type dirRecord struct {
+	ModTime time.Time
+	CTime   time.Time
+	Entries []Entry
+}
+
+// fileFormat is the on-disk representation written by Save and read by
+// Load.
+type fileFormat struct {
+	Version int
+	Dirs    map[string]dirRecord
+}
+
+// CachedWalker walks directory trees using unixdirents.Dirents, reusing a
+// directory's previous listing whenever the directory's mtime and ctime
+// have not changed since it was last read. This turns repeated recursive
+// scans of large, mostly-unchanged trees from O(files) syscalls into
+// O(changed directories).
+//
+// A CachedWalker is safe for concurrent use.
+type CachedWalker struct {
+	mu   sync.Mutex
+	dirs map[string]dirRecord
+}
+
+// New returns a CachedWalker with an empty cache. Call Load to populate it
+// from a previously saved cache file.
+func New() *CachedWalker {
+	return &CachedWalker{dirs: make(map[string]dirRecord)}
+}
+
+// Load replaces the walker's in-memory cache with the contents of the cache
+// file at path.
+func (w *CachedWalker) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var ff fileFormat
+	if err := gob.NewDecoder(f).Decode(&ff); err != nil {
+		return fmt.Errorf("cache: decode %s: %w", path, err)
+	}
+	if ff.Version != formatVersion {
+		return fmt.Errorf("cache: %s has format version %d, want %d", path, ff.Version, formatVersion)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirs = ff.Dirs
+	return nil
+}
+
+// Save writes the walker's in-memory cache to path, replacing any existing
+// file there. Save writes to a temporary file in the same directory and
+// renames it into place, so a concurrent reader of path never observes a
+// partially written cache.
+func (w *CachedWalker) Save(path string) error {
+	w.mu.Lock()
+	ff := fileFormat{Version: formatVersion, Dirs: w.dirs}
+	w.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below succeeds
+
+	if err := gob.NewEncoder(tmp).Encode(ff); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: encode %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Walk returns an iterator over directory entries by walking each file or
+// directory in the tree rooted at baseDir, including baseDir, consulting
+// and updating the walker's cache along the way.
+//
+// Note: The directory entries are not in lexical order in each directory.
+func (w *CachedWalker) Walk(baseDir string) iter.Seq2[*DirAndEntry, error] {
+	return func(yield func(*DirAndEntry, error) bool) {
+		w.walkDir(baseDir, yield)
+	}
+}
+
+func (w *CachedWalker) walkDir(dir string, yield func(*DirAndEntry, error) bool) bool {
+	entries, err := w.listDir(dir)
+	if err != nil {
+		yield(nil, err)
+		return true
+	}
+
+	for _, e := range entries {
+		if !yield(&DirAndEntry{dir: dir, entry: e}, nil) {
+			return true
+		}
+		if e.Type.IsDir() {
+			if w.walkDir(filepath.Join(dir, e.Name), yield) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listDir returns dir's entries, reusing the cached listing when dir's
+// mtime and ctime match the cached record, and otherwise reading dir afresh
+// via unixdirents and updating the cache.
+func (w *CachedWalker) listDir(dir string) ([]Entry, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, fmt.Errorf("cache: no syscall.Stat_t available for %s", dir)
+	}
+	ctime := time.Unix(st.Ctim.Sec, st.Ctim.Nsec)
+
+	w.mu.Lock()
+	rec, ok := w.dirs[dir]
+	w.mu.Unlock()
+	if ok && rec.ModTime.Equal(fi.ModTime()) && rec.CTime.Equal(ctime) {
+		return rec.Entries, nil
+	}
+
+	entries, err := readDirFresh(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	w.dirs[dir] = dirRecord{ModTime: fi.ModTime(), CTime: ctime, Entries: entries}
+	w.mu.Unlock()
+	return entries, nil
+}
+
+func readDirFresh(dir string) ([]Entry, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	var buf [4096]byte
+	for de, err := range unixdirents.Dirents(int(f.Fd()), buf[:]) {
+		if err != nil {
+			return nil, err
+		}
+		mode, err := dTypeToFileMode(dir, string(de.Name), de.Type)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{
+			Name: string(de.Name),
+			Ino:  de.Ino,
+			Type: mode,
+		})
+	}
+	return entries, nil
+}
+
+// dTypeToFileMode maps t, the d_type reported for name within dir, to the
+// corresponding fs.FileMode type bits. DTypeUnknown means the filesystem or
+// kernel did not populate d_type (older XFS, some FUSE/overlay mounts), not
+// that the entry is a regular file, so it falls back to an lstat instead of
+// defaulting to 0: CachedWalker.walkDir only recurses when Type.IsDir(), and
+// guessing wrong here would silently stop a walk from descending into a
+// real subtree.
+func dTypeToFileMode(dir, name string, t unixdirents.DType) (fs.FileMode, error) {
+	switch t {
+	case unixdirents.DTypeDir:
+		return fs.ModeDir, nil
+	case unixdirents.DTypeSymlink:
+		return fs.ModeSymlink, nil
+	case unixdirents.DTypeNamedPipe:
+		return fs.ModeNamedPipe, nil
+	case unixdirents.DTypeSocket:
+		return fs.ModeSocket, nil
+	case unixdirents.DTypeCharDevice:
+		return fs.ModeDevice | fs.ModeCharDevice, nil
+	case unixdirents.DTypeBlockDevice:
+		return fs.ModeDevice, nil
+	case unixdirents.DTypeRegularFile:
+		return 0, nil
+	default:
+		fi, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			return 0, err
+		}
+		return fi.Mode().Type(), nil
+	}
+}
+
+// PruneMissing removes cached records for directories that no longer exist
+// on disk, and returns the number of records removed.
+func (w *CachedWalker) PruneMissing() (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var removed int
+	for dir := range w.dirs {
+		if _, err := os.Stat(dir); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				delete(w.dirs, dir)
+				removed++
+				continue
+			}
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// DirAndEntry is the struct that groups a directory and one of its cached
+// entries.
+type DirAndEntry struct {
+	dir   string
+	entry Entry
+}
+
+// Dir returns the directory in a DirAndEntry struct.
+func (e *DirAndEntry) Dir() string {
+	return e.dir
+}
+
+// Entry returns the cached entry in a DirAndEntry struct.
+func (e *DirAndEntry) Entry() Entry {
+	return e.entry
+}
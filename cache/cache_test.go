@@ -0,0 +1,119 @@
+//go:build linux
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCachedWalker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "a"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "f1"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	listNames := func(w *CachedWalker) []string {
+		var names []string
+		for de, err := range w.Walk(dir) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, filepath.Join(de.Dir(), de.Entry().Name))
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	want := []string{filepath.Join(dir, "a"), filepath.Join(dir, "a", "f1")}
+
+	w := New()
+	if got := listNames(w); !slices.Equal(got, want) {
+		t.Errorf("first walk mismatch,\n got=%v,\nwant=%v", got, want)
+	}
+
+	// Second walk must reuse the cached listing for unchanged directories.
+	if got := listNames(w); !slices.Equal(got, want) {
+		t.Errorf("second walk mismatch,\n got=%v,\nwant=%v", got, want)
+	}
+
+	// Adding a new file bumps the directory's mtime/ctime, so the next walk
+	// must see it.
+	time.Sleep(10 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "a", "f2"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, filepath.Join(dir, "a", "f2"))
+	slices.Sort(want)
+	if got := listNames(w); !slices.Equal(got, want) {
+		t.Errorf("walk after add mismatch,\n got=%v,\nwant=%v", got, want)
+	}
+}
+
+func TestCachedWalkerSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "f1"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := New()
+	for _, err := range w.Walk(dir) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cachePath := filepath.Join(t.TempDir(), "cache.gob")
+	if err := w.Save(cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	w2 := New()
+	if err := w2.Load(cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for de, err := range w2.Walk(dir) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, de.Entry().Name)
+	}
+	want := []string{"f1"}
+	if !slices.Equal(names, want) {
+		t.Errorf("names mismatch,\n got=%v,\nwant=%v", names, want)
+	}
+}
+
+func TestCachedWalkerPruneMissing(t *testing.T) {
+	base := t.TempDir()
+	removedDir := filepath.Join(base, "removed")
+	if err := os.Mkdir(removedDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	w := New()
+	for _, err := range w.Walk(base) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(removedDir); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := w.PruneMissing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("removed=%d, want=1", removed)
+	}
+}
@@ -4,6 +4,7 @@ package readdiriter
 
 import (
 	"cmp"
+	"container/heap"
 	"errors"
 	"io"
 	"io/fs"
@@ -72,3 +73,93 @@ func sortDirEntriesByName(de []fs.DirEntry) {
 		return cmp.Compare(a.Name(), b.Name())
 	})
 }
+
+// NewReadDirIterSortedBatched behaves like NewReadDirIterSorted, but reads
+// file in chunks of at most batchSize entries instead of with a single
+// ReadDir(0) call, sorting each chunk as it arrives and merging the sorted
+// chunks together as they are yielded. batchSize <= 0 falls back to
+// NewReadDirIterSorted's single unbounded read.
+//
+// Producing entries in lexical order still requires having read every entry
+// in the directory before the first one can be yielded, so this does not
+// reduce peak memory below NewReadDirIterSorted's; batching only trades one
+// large ReadDir(0) call, which on some ReadDirer implementations grows its
+// result by repeated reallocation, for several fixed-size reads.
+//
+// Note: The directory entries are in lexical order.
+func NewReadDirIterSortedBatched(file ReadDirer, batchSize int) iter.Seq2[fs.DirEntry, error] {
+	if batchSize <= 0 {
+		return NewReadDirIterSorted(file)
+	}
+	return func(yield func(fs.DirEntry, error) bool) {
+		var batches [][]fs.DirEntry
+		for {
+			de, err := file.ReadDir(batchSize)
+			var seenEOF bool
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, err)
+					return
+				}
+				seenEOF = true
+			}
+			if len(de) > 0 {
+				sortDirEntriesByName(de)
+				batches = append(batches, de)
+			}
+			if seenEOF || len(de) == 0 {
+				break
+			}
+		}
+
+		h := make(dirEntryBatchHeap, 0, len(batches))
+		for _, b := range batches {
+			h = append(h, &dirEntryBatch{entries: b})
+		}
+		heap.Init(&h)
+		for h.Len() > 0 {
+			b := h[0]
+			if !yield(b.entries[b.pos], nil) {
+				return
+			}
+			b.pos++
+			if b.pos == len(b.entries) {
+				heap.Pop(&h)
+			} else {
+				heap.Fix(&h, 0)
+			}
+		}
+	}
+}
+
+// dirEntryBatch is one sorted chunk of directory entries and the merge's
+// current position within it.
+type dirEntryBatch struct {
+	entries []fs.DirEntry
+	pos     int
+}
+
+// dirEntryBatchHeap is a min-heap of dirEntryBatch by the name at each
+// batch's current position, used to merge sorted batches in
+// NewReadDirIterSortedBatched.
+type dirEntryBatchHeap []*dirEntryBatch
+
+func (h dirEntryBatchHeap) Len() int { return len(h) }
+
+func (h dirEntryBatchHeap) Less(i, j int) bool {
+	return h[i].entries[h[i].pos].Name() < h[j].entries[h[j].pos].Name()
+}
+
+func (h dirEntryBatchHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *dirEntryBatchHeap) Push(x any) {
+	*h = append(*h, x.(*dirEntryBatch))
+}
+
+func (h *dirEntryBatchHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -1,6 +1,7 @@
 package readdiriter
 
 import (
+	"io/fs"
 	"log"
 	"os"
 )
@@ -21,3 +22,28 @@ func ExampleNewReadDirIter() {
 		log.Printf("entry=%+v", entry)
 	}
 }
+
+// ExampleWalkDir shows WalkDir used as a drop-in replacement for
+// filepath.WalkDir, down to the fs.SkipDir and fs.SkipAll sentinel errors,
+// while still taking a pluggable openDir so it can walk anything that looks
+// like a directory: an afero filesystem, a testfs, a cached directory
+// reader, and so on.
+func ExampleWalkDir() {
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	err := WalkDir(".", openDir, 0, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "testdata" {
+			return fs.SkipDir
+		}
+		log.Printf("path=%s", path)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
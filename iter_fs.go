@@ -0,0 +1,104 @@
+package readdiriter
+
+import (
+	"io"
+	"io/fs"
+	"iter"
+)
+
+// FSOpenDir adapts an io/fs.FS into an OpenReadDirCloserFunc, so that
+// WalkDir, WalkDirSorted, NewReadDirIterRecursive(Sorted),
+// NewReadDirIterRecursiveParallel, NewMatchIter, and NewGlobIter can all
+// walk an arbitrary fs.FS — embed.FS, zip/tar archives,
+// testing/fstest.MapFS, afero-backed filesystems, and so on — without a
+// caller-written adapter.
+//
+// Paths passed to the returned func and reported by the resulting
+// iterators follow io/fs.FS's own convention: slash-separated, with no
+// leading slash, rather than the host OS's path convention used by the
+// os.Open-backed callers elsewhere in the package.
+//
+// The returned ReadDirCloser uses fs.ReadDirFile's own ReadDir method when
+// the fs.File opened by fsys implements it, and falls back to a single
+// fs.ReadDir call, paginated to satisfy the n parameter, when it does not.
+func FSOpenDir(fsys fs.FS) OpenReadDirCloserFunc {
+	return func(name string) (ReadDirCloser, error) {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		return &fsReadDirCloser{fsys: fsys, name: name, file: f}, nil
+	}
+}
+
+// fsReadDirCloser adapts an fs.File into a ReadDirCloser, falling back to
+// fs.ReadDir when the file itself is not an fs.ReadDirFile.
+type fsReadDirCloser struct {
+	fsys fs.FS
+	name string
+	file fs.File
+
+	fallbackEntries []fs.DirEntry
+	fallbackRead    bool
+}
+
+func (f *fsReadDirCloser) ReadDir(n int) ([]fs.DirEntry, error) {
+	if rdf, ok := f.file.(fs.ReadDirFile); ok {
+		return rdf.ReadDir(n)
+	}
+
+	if !f.fallbackRead {
+		entries, err := fs.ReadDir(f.fsys, f.name)
+		if err != nil {
+			return nil, err
+		}
+		f.fallbackEntries = entries
+		f.fallbackRead = true
+	}
+
+	if n <= 0 {
+		entries := f.fallbackEntries
+		f.fallbackEntries = nil
+		return entries, nil
+	}
+	if len(f.fallbackEntries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(f.fallbackEntries) {
+		n = len(f.fallbackEntries)
+	}
+	batch := f.fallbackEntries[:n]
+	f.fallbackEntries = f.fallbackEntries[n:]
+	return batch, nil
+}
+
+func (f *fsReadDirCloser) Close() error {
+	return f.file.Close()
+}
+
+// NewFSReadDirIterRecursive is the fs.FS-backed counterpart of
+// NewReadDirIterRecursive: it walks the tree rooted at root within fsys
+// instead of requiring a caller-supplied OpenReadDirCloserFunc.
+//
+// Note: The directory entries are not in lexical order in each directory.
+//
+// Deprecated: the skipDir pointer must be mutated from inside the range
+// loop, which cannot express skipping the remainder of a directory for a
+// non-directory entry or stopping the walk cleanly. Use
+// WalkDir(root, FSOpenDir(fsys), n, fn) instead.
+func NewFSReadDirIterRecursive(fsys fs.FS, root string, n int, skipDir *bool) iter.Seq2[*DirAndEntry, error] {
+	return NewReadDirIterRecursive(root, FSOpenDir(fsys), n, skipDir)
+}
+
+// NewFSReadDirIterRecursiveSorted is the fs.FS-backed counterpart of
+// NewReadDirIterRecursiveSorted.
+//
+// Note: The directory entries are in lexical order in each directory.
+//
+// Deprecated: the skipDir pointer must be mutated from inside the range
+// loop, which cannot express skipping the remainder of a directory for a
+// non-directory entry or stopping the walk cleanly. Use
+// WalkDirSorted(root, FSOpenDir(fsys), fn) instead.
+func NewFSReadDirIterRecursiveSorted(fsys fs.FS, root string, skipDir *bool) iter.Seq2[*DirAndEntry, error] {
+	return NewReadDirIterRecursiveSorted(root, FSOpenDir(fsys), skipDir)
+}
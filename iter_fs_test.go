@@ -0,0 +1,69 @@
+package readdiriter
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSOpenDir(t *testing.T) {
+	mapFS := fstest.MapFS{
+		"a/f1":   &fstest.MapFile{Data: []byte("f1")},
+		"a/f2":   &fstest.MapFile{Data: []byte("f2")},
+		"a/b/f3": &fstest.MapFile{Data: []byte("f3")},
+		"c/f4":   &fstest.MapFile{Data: []byte("f4")},
+	}
+
+	if err := fstest.TestFS(mapFS, "a/f1", "a/f2", "a/b/f3", "c/f4"); err != nil {
+		t.Fatalf("fstest.TestFS: %s", err)
+	}
+
+	var gotPaths []string
+	for de, err := range NewFSReadDirIterRecursive(mapFS, ".", 0, new(bool)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		gotPaths = append(gotPaths, filepath.Join(de.Dir(), de.Entry().Name()))
+	}
+	slices.Sort(gotPaths)
+
+	want := []string{
+		"a",
+		"c",
+		"a/b",
+		"a/f1",
+		"a/f2",
+		"a/b/f3",
+		"c/f4",
+	}
+	slices.Sort(want)
+	if !slices.Equal(gotPaths, want) {
+		t.Errorf("gotPaths=%v, want=%v", gotPaths, want)
+	}
+}
+
+func TestFSOpenDir_WalkDir(t *testing.T) {
+	mapFS := fstest.MapFS{
+		"a/f1":   &fstest.MapFile{Data: []byte("f1")},
+		"a/b/f2": &fstest.MapFile{Data: []byte("f2")},
+	}
+
+	var gotPaths []string
+	err := WalkDirSorted(".", FSOpenDir(mapFS), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		gotPaths = append(gotPaths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"a", "a/b", "a/b/f2", "a/f1"}
+	if !slices.Equal(gotPaths, want) {
+		t.Errorf("gotPaths=%v, want=%v", gotPaths, want)
+	}
+}
@@ -0,0 +1,242 @@
+package readdiriter
+
+import (
+	"io/fs"
+	"iter"
+	"path/filepath"
+	"strings"
+)
+
+// MatchFunc reports, for the entry at path, whether it should be kept in
+// the iteration (keep) and, when entry is a directory, whether NewMatchIter
+// should descend into it (descend). Returning descend false for a
+// directory prunes the entire subtree without ever opening it, unlike
+// filtering the entries NewReadDirIterRecursive already produced.
+type MatchFunc func(path string, entry fs.DirEntry) (keep, descend bool)
+
+// NewMatchIter returns an iterator over directory entries by walking the
+// tree rooted at baseDir, the same starting point as
+// NewReadDirIterRecursive, calling match for each entry found.
+//
+// match's descend return is checked before a matching directory is opened,
+// so returning false there is the way to exclude subtrees such as .git,
+// node_modules, or vendor without paying the cost of reading them.
+//
+// Note: The directory entries are not in lexical order in each directory.
+func NewMatchIter(baseDir string, openDir OpenReadDirCloserFunc, match MatchFunc) iter.Seq2[*DirAndEntry, error] {
+	return func(yield func(*DirAndEntry, error) bool) {
+		matchDir(baseDir, openDir, match, yield)
+	}
+}
+
+func matchDir(dir string, openDir OpenReadDirCloserFunc, match MatchFunc, yield func(*DirAndEntry, error) bool) bool {
+	dirFile, err := openDir(dir)
+	if err != nil {
+		return !yield(nil, err)
+	}
+	defer dirFile.Close()
+
+	for entry, err := range NewReadDirIter(dirFile, 0) {
+		if err != nil {
+			if !yield(nil, err) {
+				return true
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		keep, descend := match(path, entry)
+		if keep {
+			if !yield(&DirAndEntry{dir: dir, entry: entry}, nil) {
+				return true
+			}
+		}
+		if entry.IsDir() && descend {
+			if matchDir(path, openDir, match, yield) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewGlobIter returns an iterator over the paths within baseDir that match
+// pattern, built on NewMatchIter so that directories pattern cannot match
+// are pruned before they are opened.
+//
+// pattern is a slash-separated sequence of segments, each matched against
+// one path component with the syntax of path.Match, with one addition: a
+// "**" segment matches zero or more path components, mirroring the
+// recursive-wildcard behavior of fd and ripgrep. For example "**/*.go"
+// matches every *.go file in baseDir and its subdirectories, including
+// baseDir's own *.go files.
+//
+// Paths are reported relative to baseDir, using baseDir's own separator
+// convention.
+//
+// Note: The paths are not produced in lexical order.
+func NewGlobIter(baseDir string, openDir OpenReadDirCloserFunc, pattern string) iter.Seq2[string, error] {
+	g := compileGlob(pattern)
+
+	match := func(path string, entry fs.DirEntry) (keep, descend bool) {
+		rel, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			rel = path
+		}
+		segments := splitPathSegments(rel)
+		keep = g.match(segments)
+		descend = entry.IsDir() && g.mayDescend(segments)
+		return keep, descend
+	}
+
+	return func(yield func(string, error) bool) {
+		for de, err := range NewMatchIter(baseDir, openDir, match) {
+			if err != nil {
+				if !yield("", err) {
+					return
+				}
+				continue
+			}
+			rel, err := filepath.Rel(baseDir, filepath.Join(de.Dir(), de.Entry().Name()))
+			if err != nil {
+				if !yield("", err) {
+					return
+				}
+				continue
+			}
+			if !yield(rel, nil) {
+				return
+			}
+		}
+	}
+}
+
+// globPattern is a compiled glob pattern split into slash-separated
+// segments, one of which may be "**".
+type globPattern struct {
+	segments []string
+}
+
+func compileGlob(pattern string) *globPattern {
+	return &globPattern{segments: strings.Split(filepath.ToSlash(pattern), "/")}
+}
+
+func splitPathSegments(path string) []string {
+	path = filepath.ToSlash(path)
+	if path == "" || path == "." {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// match reports whether path, given as segments, matches the full pattern.
+func (g *globPattern) match(segments []string) bool {
+	return matchSegments(g.segments, segments)
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		if matchSegments(pattern[1:], path) {
+			return true
+		}
+		if len(path) == 0 {
+			return false
+		}
+		return matchSegments(pattern, path[1:])
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
+
+// mayDescend reports whether a directory at the given path segments could
+// still contain an entry matching the pattern. Once a "**" segment is
+// reached, it can absorb any number of further directories, so every
+// deeper directory is kept; segments before that point must each match
+// their path component, by path.Match syntax, or pruning stops the walk
+// there.
+func (g *globPattern) mayDescend(segments []string) bool {
+	return mayDescendSegments(g.segments, segments)
+}
+
+func mayDescendSegments(pattern, dir []string) bool {
+	if len(dir) == 0 {
+		return true
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+	ok, err := filepath.Match(pattern[0], dir[0])
+	if err != nil || !ok {
+		return false
+	}
+	return mayDescendSegments(pattern[1:], dir[1:])
+}
+
+// filterSet compiles RecursiveOptions.Include and Exclude into glob
+// patterns matched against a path's components relative to baseDir.
+type filterSet struct {
+	baseDir string
+	include []*globPattern
+	exclude []*globPattern
+}
+
+func newFilterSet(baseDir string, include, exclude []string) *filterSet {
+	fs := &filterSet{baseDir: baseDir}
+	for _, pattern := range include {
+		fs.include = append(fs.include, compileGlob(pattern))
+	}
+	for _, pattern := range exclude {
+		fs.exclude = append(fs.exclude, compileGlob(pattern))
+	}
+	return fs
+}
+
+func (fs *filterSet) relSegments(path string) []string {
+	rel, err := filepath.Rel(fs.baseDir, path)
+	if err != nil {
+		rel = path
+	}
+	return splitPathSegments(rel)
+}
+
+// excluded reports whether path matches one of fs's exclude patterns.
+func (fs *filterSet) excluded(path string) bool {
+	if len(fs.exclude) == 0 {
+		return false
+	}
+	segments := fs.relSegments(path)
+	for _, g := range fs.exclude {
+		if g.match(segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// included reports whether path matches one of fs's include patterns, or
+// whether fs has no include patterns at all, in which case every path is
+// considered included.
+func (fs *filterSet) included(path string) bool {
+	if len(fs.include) == 0 {
+		return true
+	}
+	segments := fs.relSegments(path)
+	for _, g := range fs.include {
+		if g.match(segments) {
+			return true
+		}
+	}
+	return false
+}
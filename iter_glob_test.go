@@ -0,0 +1,193 @@
+package readdiriter
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNewMatchIter(t *testing.T) {
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	t.Run("pruneSubtree", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "a", "b")
+		mustMkdirAll(t, dir, "a", "vendor", "c")
+		mustWriteFile(t, dir, filepath.Join("a", "f1"))
+		mustWriteFile(t, dir, filepath.Join("a", "vendor", "f2"))
+		mustWriteFile(t, dir, filepath.Join("a", "vendor", "c", "f3"))
+
+		var visited []string
+		var gotPaths []string
+		for de, err := range NewMatchIter(dir, openDir, func(path string, entry fs.DirEntry) (keep, descend bool) {
+			rel, _ := filepath.Rel(dir, path)
+			visited = append(visited, rel)
+			if filepath.Base(path) == "vendor" {
+				return true, false
+			}
+			return true, true
+		}) {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			rel, _ := filepath.Rel(dir, filepath.Join(de.Dir(), de.Entry().Name()))
+			gotPaths = append(gotPaths, rel)
+		}
+		slices.Sort(gotPaths)
+
+		want := []string{
+			"a",
+			filepath.Join("a", "b"),
+			filepath.Join("a", "f1"),
+			filepath.Join("a", "vendor"),
+		}
+		slices.Sort(want)
+		if !slices.Equal(gotPaths, want) {
+			t.Errorf("gotPaths=%v, want=%v", gotPaths, want)
+		}
+
+		for _, p := range visited {
+			if filepath.Base(p) == "c" {
+				t.Errorf("visited pruned subtree entry %q", p)
+			}
+		}
+	})
+}
+
+func TestNewGlobIter(t *testing.T) {
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	dir := tempDir(t)
+	mustMkdirAll(t, dir, "a", "b")
+	mustMkdirAll(t, dir, "node_modules", "pkg")
+	mustWriteFile(t, dir, "top.go")
+	mustWriteFile(t, dir, filepath.Join("a", "f1.go"))
+	mustWriteFile(t, dir, filepath.Join("a", "f1.txt"))
+	mustWriteFile(t, dir, filepath.Join("a", "b", "f2.go"))
+	mustWriteFile(t, dir, filepath.Join("node_modules", "pkg", "f3.go"))
+
+	testCases := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "topLevelOnly",
+			pattern: "*.go",
+			want:    []string{"top.go"},
+		},
+		{
+			name:    "recursive",
+			pattern: "**/*.go",
+			want: []string{
+				"top.go",
+				filepath.Join("a", "f1.go"),
+				filepath.Join("a", "b", "f2.go"),
+				filepath.Join("node_modules", "pkg", "f3.go"),
+			},
+		},
+		{
+			name:    "oneLevelDeep",
+			pattern: "a/*.go",
+			want:    []string{filepath.Join("a", "f1.go")},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for path, err := range NewGlobIter(dir, openDir, tc.pattern) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				got = append(got, path)
+			}
+			slices.Sort(got)
+			want := slices.Clone(tc.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func mustMkdirAll(t testing.TB, base string, parts ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(append([]string{base}, parts...)...), 0o700); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustWriteFile(t testing.TB, base, rel string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(base, rel), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// benchTreeForGlob builds a tree with a large "vendor" subtree alongside a
+// much smaller set of matching files, so a benchmark can show that pruning
+// avoids walking the excluded subtree at all.
+func benchTreeForGlob(b *testing.B) string {
+	b.Helper()
+	dir := b.TempDir()
+	mustMkdirAll(b, dir, "src")
+	for i := range 20 {
+		mustWriteFile(b, dir, filepath.Join("src", fmt.Sprintf("f%d.go", i)))
+	}
+	for i := range 50 {
+		sub := filepath.Join("vendor", fmt.Sprintf("pkg%d", i))
+		mustMkdirAll(b, dir, sub)
+		for j := range 200 {
+			mustWriteFile(b, dir, filepath.Join(sub, fmt.Sprintf("f%d.go", j)))
+		}
+	}
+	return dir
+}
+
+// BenchmarkNewGlobIter_PruneVendor shows the cost of excluding a large
+// vendor subtree via NewMatchIter's descend pruning, compared to matching
+// against the full tree including vendor.
+func BenchmarkNewGlobIter_PruneVendor(b *testing.B) {
+	dir := benchTreeForGlob(b)
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	b.Run("withVendor", func(b *testing.B) {
+		for range b.N {
+			n := 0
+			for _, err := range NewGlobIter(dir, openDir, "**/*.go") {
+				if err != nil {
+					b.Fatalf("unexpected error: %s", err)
+				}
+				n++
+			}
+		}
+	})
+
+	b.Run("prunedVendor", func(b *testing.B) {
+		match := func(path string, entry fs.DirEntry) (keep, descend bool) {
+			if entry.IsDir() {
+				return false, filepath.Base(path) != "vendor"
+			}
+			return filepath.Ext(path) == ".go", false
+		}
+		for range b.N {
+			n := 0
+			for _, err := range NewMatchIter(dir, openDir, match) {
+				if err != nil {
+					b.Fatalf("unexpected error: %s", err)
+				}
+				n++
+			}
+		}
+	})
+}
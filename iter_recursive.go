@@ -39,6 +39,10 @@ func (e *DirAndEntry) Entry() fs.DirEntry {
 // https://pkg.go.dev/io/fs@latest#ReadDirFile.
 //
 // Note: The directory entries are not in lexical order in each directory.
+//
+// Deprecated: the skipDir pointer must be mutated from inside the range
+// loop, which cannot express skipping the remainder of a directory for a
+// non-directory entry or stopping the walk cleanly. Use WalkDir instead.
 func NewReadDirIterRecursive(baseDir string, openDir OpenReadDirCloserFunc, n int, skipDir *bool) iter.Seq2[*DirAndEntry, error] {
 	return func(yield func(*DirAndEntry, error) bool) {
 		walkDir(baseDir, openDir, n, skipDir, yield)
@@ -88,6 +92,10 @@ func walkDir(baseDir string, openDir OpenReadDirCloserFunc, n int, skipDir *bool
 // each file or directory in the tree, including baseDir.
 //
 // Note: The directory entries are in lexical order in each directory.
+//
+// Deprecated: the skipDir pointer must be mutated from inside the range
+// loop, which cannot express skipping the remainder of a directory for a
+// non-directory entry or stopping the walk cleanly. Use WalkDirSorted instead.
 func NewReadDirIterRecursiveSorted(baseDir string, openDir OpenReadDirCloserFunc, skipDir *bool) iter.Seq2[*DirAndEntry, error] {
 	return func(yield func(*DirAndEntry, error) bool) {
 		walkDirSorted(baseDir, openDir, skipDir, yield)
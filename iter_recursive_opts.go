@@ -0,0 +1,236 @@
+package readdiriter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// SymlinkMode controls how NewReadDirIterRecursiveOpts treats symbolic
+// links to directories.
+type SymlinkMode int
+
+const (
+	// SymlinkSkip treats symlinks as opaque leaf entries: they are
+	// reported but never followed, matching the behavior of WalkDir and
+	// NewReadDirIterRecursive. This is the zero value.
+	SymlinkSkip SymlinkMode = iota
+
+	// SymlinkFollowFiles follows a symlink only far enough to tell
+	// whether it ultimately resolves to a directory; either way, the walk
+	// does not descend into it. The entries yielded are the same as under
+	// SymlinkSkip: a symlink's DirEntry still reports fs.ModeSymlink, not
+	// the type of its target.
+	SymlinkFollowFiles
+
+	// SymlinkFollowAll follows symlinks to directories and descends into
+	// them, with cycle detection to guard against symlink loops.
+	SymlinkFollowAll
+)
+
+// ErrSymlinkCycle is wrapped into the error yielded in place of descending
+// into a directory symlink whose target is baseDir or a directory
+// NewReadDirIterRecursiveOpts has already entered through a symlink.
+var ErrSymlinkCycle = errors.New("readdiriter: symlink cycle detected")
+
+// RecursiveOptions configures NewReadDirIterRecursiveOpts.
+type RecursiveOptions struct {
+	// N follows the semantics of fs.ReadDirFile and is passed through to
+	// each directory read: https://pkg.go.dev/io/fs@latest#ReadDirFile.
+	N int
+
+	// Symlink controls how symlinks to directories are treated. The zero
+	// value is SymlinkSkip.
+	Symlink SymlinkMode
+
+	// Include, if non-empty, restricts the files yielded to those whose
+	// path relative to baseDir matches at least one of these patterns.
+	// Directories are unaffected by Include: they are still yielded and
+	// still descended into, since a match deeper in the tree can only be
+	// found by walking through them.
+	//
+	// Patterns use the slash-separated syntax NewGlobIter accepts: each
+	// segment is matched with the syntax of path.Match, and a "**"
+	// segment matches zero or more path components.
+	Include []string
+
+	// Exclude, if non-empty, prunes any file or directory whose path
+	// relative to baseDir matches at least one of these patterns. A
+	// matching directory is skipped without ever being descended into,
+	// equivalent to setting skipDir on NewReadDirIterRecursive; a
+	// matching file is simply dropped. Exclude is checked before
+	// Include, so an excluded path is pruned even if it would also match
+	// an Include pattern.
+	//
+	// Patterns use the same syntax as Include.
+	Exclude []string
+}
+
+// NewReadDirIterRecursiveOpts returns an iterator over directory entries by
+// walking each file or directory in the tree rooted at baseDir, including
+// baseDir, with symlink behavior controlled by opts.Symlink.
+//
+// When opts.Symlink is SymlinkFollowAll, cycles are detected by tracking
+// the (dev, ino) pair of baseDir and of each directory entered through a
+// symlink, read via os.Stat and the platform's syscall.Stat_t, analogous to
+// the dev/ino file identity used by build systems such as kati to dedup
+// files. On platforms where syscall.Stat_t is unavailable, those
+// directories are tracked by their canonicalized path via
+// filepath.EvalSymlinks instead. A directory symlink whose target is
+// already tracked is not descended into; an error wrapping ErrSymlinkCycle
+// is yielded in its place so callers can log or otherwise handle the skip.
+// Directories reached without a symlink are not tracked, so a symlink to
+// one of them is followed and its contents are yielded a second time.
+//
+// When opts.Include or opts.Exclude is non-empty, entries are additionally
+// filtered by newFilterSet: an excluded directory is neither yielded nor
+// descended into, an excluded file is dropped, and a file not matching any
+// Include pattern is dropped.
+//
+// Note: The directory entries are not in lexical order in each directory.
+func NewReadDirIterRecursiveOpts(baseDir string, openDir OpenReadDirCloserFunc, opts RecursiveOptions) iter.Seq2[*DirAndEntry, error] {
+	return func(yield func(*DirAndEntry, error) bool) {
+		w := &symlinkWalker{
+			openDir:      openDir,
+			n:            opts.N,
+			mode:         opts.Symlink,
+			filter:       newFilterSet(baseDir, opts.Include, opts.Exclude),
+			visited:      make(map[fileKey]bool),
+			visitedPaths: make(map[string]bool),
+		}
+		w.markVisited(baseDir)
+		w.walk(baseDir, yield)
+	}
+}
+
+// fileKey identifies a file by its (dev, ino) pair, the same identity
+// symlink cycle detection in tools like make(1) and kati use to tell
+// whether two paths name the same underlying file.
+type fileKey struct {
+	dev uint64
+	ino uint64
+}
+
+// symlinkWalker holds the shared state for one call to
+// NewReadDirIterRecursiveOpts.
+type symlinkWalker struct {
+	openDir OpenReadDirCloserFunc
+	n       int
+	mode    SymlinkMode
+	filter  *filterSet
+
+	visited      map[fileKey]bool
+	visitedPaths map[string]bool
+}
+
+// markVisited records dir as visited ahead of the walk, so that a symlink
+// cycling back to baseDir itself is detected.
+func (w *symlinkWalker) markVisited(dir string) {
+	if w.mode != SymlinkFollowAll {
+		return
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return
+	}
+	w.recordVisited(dir, fi)
+}
+
+// recordVisited marks fi, the already-stat'd result for path, as visited,
+// using the platform's (dev, ino) pair when available and the
+// canonicalized path otherwise. It reports whether path was already
+// visited.
+func (w *symlinkWalker) recordVisited(path string, fi fs.FileInfo) bool {
+	if key, ok := fileKeyOf(fi); ok {
+		if w.visited[key] {
+			return true
+		}
+		w.visited[key] = true
+		return false
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// Treat an unresolvable path as unvisited: shouldDescend will
+		// most likely fail on it again with the same error shortly.
+		return false
+	}
+	if w.visitedPaths[resolved] {
+		return true
+	}
+	w.visitedPaths[resolved] = true
+	return false
+}
+
+func (w *symlinkWalker) walk(dir string, yield func(*DirAndEntry, error) bool) bool {
+	dirFile, err := w.openDir(dir)
+	if err != nil {
+		return !yield(nil, err)
+	}
+	defer dirFile.Close()
+
+	for entry, err := range NewReadDirIter(dirFile, w.n) {
+		if err != nil {
+			if !yield(nil, err) {
+				return true
+			}
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if w.filter.excluded(path) {
+			continue
+		}
+		if !entry.IsDir() && !w.filter.included(path) {
+			continue
+		}
+
+		if !yield(&DirAndEntry{dir: dir, entry: entry}, nil) {
+			return true
+		}
+
+		descend, cycleErr := w.shouldDescend(path, entry)
+		if cycleErr != nil {
+			if !yield(nil, cycleErr) {
+				return true
+			}
+			continue
+		}
+		if descend {
+			if w.walk(path, yield) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shouldDescend reports whether path, the joined path of entry within dir,
+// should be walked into. For a directory symlink that would revisit an
+// already-visited directory, it returns a non-nil error wrapping
+// ErrSymlinkCycle instead.
+func (w *symlinkWalker) shouldDescend(path string, entry fs.DirEntry) (bool, error) {
+	if entry.IsDir() {
+		return true, nil
+	}
+	if entry.Type()&fs.ModeSymlink == 0 || w.mode == SymlinkSkip {
+		return false, nil
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		// A broken or inaccessible symlink is left as the plain,
+		// non-directory entry already yielded above, not an error here.
+		return false, nil
+	}
+	if !fi.IsDir() || w.mode == SymlinkFollowFiles {
+		return false, nil
+	}
+
+	if w.recordVisited(path, fi) {
+		return false, fmt.Errorf("%s: %w", path, ErrSymlinkCycle)
+	}
+	return true, nil
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package readdiriter
+
+import "io/fs"
+
+// fileKeyOf always reports false on non-unix platforms, since there is no
+// syscall.Stat_t to extract a (dev, ino) pair from; recordVisited falls
+// back to filepath.EvalSymlinks instead.
+func fileKeyOf(fi fs.FileInfo) (fileKey, bool) {
+	return fileKey{}, false
+}
@@ -0,0 +1,184 @@
+package readdiriter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNewReadDirIterRecursiveOpts(t *testing.T) {
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	collect := func(t *testing.T, dir string, opts RecursiveOptions) (paths []string, errs []error) {
+		for de, err := range NewReadDirIterRecursiveOpts(dir, openDir, opts) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			paths = append(paths, filepath.Join(de.Dir(), de.Entry().Name()))
+		}
+		return paths, errs
+	}
+
+	t.Run("skipDoesNotFollowDirSymlink", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "a")
+		mustWriteFile(t, dir, filepath.Join("a", "f1"))
+		if err := os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "link")); err != nil {
+			t.Fatal(err)
+		}
+
+		paths, errs := collect(t, dir, RecursiveOptions{Symlink: SymlinkSkip})
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		slices.Sort(paths)
+		want := []string{
+			filepath.Join(dir, "a"),
+			filepath.Join(dir, "a", "f1"),
+			filepath.Join(dir, "link"),
+		}
+		slices.Sort(want)
+		if !slices.Equal(paths, want) {
+			t.Errorf("paths=%v, want=%v", paths, want)
+		}
+	})
+
+	t.Run("followAllDescendsIntoDirSymlink", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "a")
+		mustWriteFile(t, dir, filepath.Join("a", "f1"))
+		if err := os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "link")); err != nil {
+			t.Fatal(err)
+		}
+
+		paths, errs := collect(t, dir, RecursiveOptions{Symlink: SymlinkFollowAll})
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		slices.Sort(paths)
+		want := []string{
+			filepath.Join(dir, "a"),
+			filepath.Join(dir, "a", "f1"),
+			filepath.Join(dir, "link"),
+			filepath.Join(dir, "link", "f1"),
+		}
+		slices.Sort(want)
+		if !slices.Equal(paths, want) {
+			t.Errorf("paths=%v, want=%v", paths, want)
+		}
+	})
+
+	t.Run("followAllDetectsCycle", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "a")
+		if err := os.Symlink(dir, filepath.Join(dir, "a", "self")); err != nil {
+			t.Fatal(err)
+		}
+
+		_, errs := collect(t, dir, RecursiveOptions{Symlink: SymlinkFollowAll})
+		if len(errs) != 1 {
+			t.Fatalf("errs=%v, want exactly one ErrSymlinkCycle", errs)
+		}
+		if !errors.Is(errs[0], ErrSymlinkCycle) {
+			t.Errorf("errs[0]=%v, want it to wrap ErrSymlinkCycle", errs[0])
+		}
+	})
+
+	t.Run("followFilesDoesNotDescendIntoDirSymlink", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "a")
+		mustWriteFile(t, dir, filepath.Join("a", "f1"))
+		mustWriteFile(t, dir, "file")
+		if err := os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "link")); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(dir, "file"), filepath.Join(dir, "filelink")); err != nil {
+			t.Fatal(err)
+		}
+
+		paths, errs := collect(t, dir, RecursiveOptions{Symlink: SymlinkFollowFiles})
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		slices.Sort(paths)
+		want := []string{
+			filepath.Join(dir, "a"),
+			filepath.Join(dir, "a", "f1"),
+			filepath.Join(dir, "file"),
+			filepath.Join(dir, "filelink"),
+			filepath.Join(dir, "link"),
+		}
+		slices.Sort(want)
+		if !slices.Equal(paths, want) {
+			t.Errorf("paths=%v, want=%v", paths, want)
+		}
+	})
+
+	t.Run("excludePrunesDirWithoutDescent", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "src")
+		mustMkdirAll(t, dir, "vendor", "pkg")
+		mustWriteFile(t, dir, filepath.Join("src", "f1.go"))
+		mustWriteFile(t, dir, filepath.Join("vendor", "pkg", "f2.go"))
+
+		paths, errs := collect(t, dir, RecursiveOptions{Exclude: []string{"**/vendor/**", "vendor"}})
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		slices.Sort(paths)
+		want := []string{
+			filepath.Join(dir, "src"),
+			filepath.Join(dir, "src", "f1.go"),
+		}
+		slices.Sort(want)
+		if !slices.Equal(paths, want) {
+			t.Errorf("paths=%v, want=%v", paths, want)
+		}
+	})
+
+	t.Run("includeDropsNonMatchingFilesButKeepsDirs", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "a")
+		mustWriteFile(t, dir, filepath.Join("a", "f1.go"))
+		mustWriteFile(t, dir, filepath.Join("a", "f1.txt"))
+
+		paths, errs := collect(t, dir, RecursiveOptions{Include: []string{"**/*.go"}})
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		slices.Sort(paths)
+		want := []string{
+			filepath.Join(dir, "a"),
+			filepath.Join(dir, "a", "f1.go"),
+		}
+		slices.Sort(want)
+		if !slices.Equal(paths, want) {
+			t.Errorf("paths=%v, want=%v", paths, want)
+		}
+	})
+
+	t.Run("excludeWinsOverInclude", func(t *testing.T) {
+		dir := tempDir(t)
+		mustMkdirAll(t, dir, "vendor")
+		mustWriteFile(t, dir, filepath.Join("vendor", "f1.go"))
+		mustWriteFile(t, dir, "f2.go")
+
+		paths, errs := collect(t, dir, RecursiveOptions{
+			Include: []string{"**/*.go"},
+			Exclude: []string{"vendor", "**/vendor/**"},
+		})
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		slices.Sort(paths)
+		want := []string{filepath.Join(dir, "f2.go")}
+		if !slices.Equal(paths, want) {
+			t.Errorf("paths=%v, want=%v", paths, want)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package readdiriter
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileKeyOf extracts fi's (dev, ino) pair via the platform's syscall.Stat_t,
+// the same file identity build systems such as kati use to dedup files. It
+// reports false when fi.Sys() is not a *syscall.Stat_t, which recordVisited
+// treats as unavailable and falls back to filepath.EvalSymlinks instead.
+func fileKeyOf(fi fs.FileInfo) (fileKey, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileKey{}, false
+	}
+	return fileKey{dev: uint64(st.Dev), ino: st.Ino}, true
+}
@@ -0,0 +1,268 @@
+package readdiriter
+
+import (
+	"io/fs"
+	"iter"
+	"path/filepath"
+	"sync"
+)
+
+// ParallelOptions configures NewReadDirIterRecursiveParallel.
+type ParallelOptions struct {
+	// Concurrency is the maximum number of directories read concurrently.
+	// Values <= 0 are treated as 1, which behaves like a serial walk.
+	Concurrency int
+
+	// N follows the semantics of fs.ReadDirFile and is passed through to
+	// each directory read: https://pkg.go.dev/io/fs@latest#ReadDirFile.
+	N int
+
+	// Ordered, when true, emits each directory's entries in
+	// parent-before-children order, as a serial depth-first walk would,
+	// even though directories are still read concurrently in the
+	// background. When false, entries are streamed as soon as any worker
+	// produces them, which maximizes throughput but gives no ordering
+	// guarantee across directories.
+	Ordered bool
+
+	// Descend, if non-nil, is called for every entry found that is a
+	// directory and reports whether the walk should read that subdirectory. Returning
+	// false prunes the subtree before any of its goroutines are started.
+	// A nil Descend descends into every directory, the previous behavior.
+	//
+	// Descend replaces the shared *bool skipDir pointer used by
+	// NewReadDirIterRecursive: directories here are read from multiple
+	// goroutines at once, and a shared bool cannot be mutated safely
+	// across them.
+	Descend func(dir string, entry fs.DirEntry) bool
+}
+
+// dirListing is the result of reading one directory's entries.
+type dirListing struct {
+	entries []fs.DirEntry
+	err     error
+}
+
+// NewReadDirIterRecursiveParallel returns an iterator over directory entries
+// by walking each file or directory in the tree, including baseDir, using a
+// bounded pool of goroutines to read subdirectories concurrently.
+//
+// This benefits large trees on storage where concurrent getdents calls on
+// independent directories outperform a single serial walk, such as NVMe,
+// network filesystems, or FUSE.
+//
+// Breaking out of the range loop cancels all outstanding directory reads;
+// NewReadDirIterRecursiveParallel does not leak goroutines or file
+// descriptors after the iterator stops, whether it stopped because the
+// consumer broke out of the loop or because the walk completed or errored.
+//
+// Note: unless opts.Ordered is set, the directory entries are not in any
+// particular order, neither within nor across directories.
+func NewReadDirIterRecursiveParallel(baseDir string, openDir OpenReadDirCloserFunc, opts ParallelOptions) iter.Seq2[*DirAndEntry, error] {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return func(yield func(*DirAndEntry, error) bool) {
+		w := &parallelWalker{
+			openDir: openDir,
+			n:       opts.N,
+			descend: opts.Descend,
+			sem:     make(chan struct{}, concurrency),
+			done:    make(chan struct{}),
+		}
+		// Deferred in this order so close runs before Wait: closing done
+		// first lets any workers still blocked on a send or the semaphore
+		// unblock via their <-w.done case, so Wait actually returns.
+		defer w.wg.Wait()
+		defer close(w.done)
+
+		if opts.Ordered {
+			w.walkOrdered(baseDir, nil, yield)
+		} else {
+			w.walkUnordered(baseDir, yield)
+		}
+	}
+}
+
+// parallelWalker holds the shared state for one call to
+// NewReadDirIterRecursiveParallel.
+type parallelWalker struct {
+	openDir OpenReadDirCloserFunc
+	n       int
+	descend func(dir string, entry fs.DirEntry) bool
+	sem     chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// shouldDescend reports whether entry, found in dir, is a directory the
+// walk should read. It consults w.descend when set, so that callers can
+// prune subtrees such as .git, node_modules, or vendor before any
+// goroutine is started for them.
+func (w *parallelWalker) shouldDescend(dir string, entry fs.DirEntry) bool {
+	if !entry.IsDir() {
+		return false
+	}
+	if w.descend == nil {
+		return true
+	}
+	return w.descend(dir, entry)
+}
+
+// readDir opens dir, reads all of its entries, and closes it again, blocking
+// on the walker's semaphore so that at most Concurrency directories are read
+// at once.
+func (w *parallelWalker) readDir(dir string) dirListing {
+	select {
+	case w.sem <- struct{}{}:
+	case <-w.done:
+		return dirListing{}
+	}
+	defer func() { <-w.sem }()
+
+	dirFile, err := w.openDir(dir)
+	if err != nil {
+		return dirListing{err: err}
+	}
+	defer dirFile.Close()
+
+	var listing dirListing
+	for entry, err := range NewReadDirIter(dirFile, w.n) {
+		if err != nil {
+			return dirListing{entries: listing.entries, err: err}
+		}
+		listing.entries = append(listing.entries, entry)
+	}
+	return listing
+}
+
+// fetch starts reading dir in the background and returns a channel that
+// receives exactly one dirListing once the read completes.
+func (w *parallelWalker) fetch(dir string) chan dirListing {
+	ch := make(chan dirListing, 1)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ch <- w.readDir(dir)
+	}()
+	return ch
+}
+
+// walkUnordered streams entries as soon as any in-flight directory read
+// produces them, with no ordering guarantee.
+func (w *parallelWalker) walkUnordered(baseDir string, yield func(*DirAndEntry, error) bool) bool {
+	listing := w.readDir(baseDir)
+	if listing.err != nil {
+		return !yield(nil, listing.err)
+	}
+
+	results := make(chan *DirAndEntry)
+	errs := make(chan error)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.fanOutUnordered(baseDir, listing.entries, results, errs)
+		close(results)
+		close(errs)
+	}()
+
+	for {
+		select {
+		case de, ok := <-results:
+			if !ok {
+				return false
+			}
+			if !yield(de, nil) {
+				return true
+			}
+		case err, ok := <-errs:
+			if !ok {
+				continue
+			}
+			if !yield(nil, err) {
+				return true
+			}
+		case <-w.done:
+			return true
+		}
+	}
+}
+
+// fanOutUnordered emits entries's own entries and recurses into
+// subdirectories concurrently, feeding results and errs.
+func (w *parallelWalker) fanOutUnordered(dir string, entries []fs.DirEntry, results chan<- *DirAndEntry, errs chan<- error) {
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		select {
+		case results <- &DirAndEntry{dir: dir, entry: entry}:
+		case <-w.done:
+			wg.Wait()
+			return
+		}
+		if !w.shouldDescend(dir, entry) {
+			continue
+		}
+
+		subDir := filepath.Join(dir, entry.Name())
+		wg.Add(1)
+		w.wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer w.wg.Done()
+			listing := w.readDir(subDir)
+			if listing.err != nil {
+				select {
+				case errs <- listing.err:
+				case <-w.done:
+				}
+				return
+			}
+			w.fanOutUnordered(subDir, listing.entries, results, errs)
+		}()
+	}
+	wg.Wait()
+}
+
+// walkOrdered walks baseDir, emitting every directory's entries in
+// parent-before-children order while still reading directories
+// concurrently in the background. ch is the in-flight fetch for baseDir, or
+// nil if it has not been dispatched yet.
+func (w *parallelWalker) walkOrdered(dir string, ch chan dirListing, yield func(*DirAndEntry, error) bool) bool {
+	if ch == nil {
+		ch = w.fetch(dir)
+	}
+
+	var listing dirListing
+	select {
+	case listing = <-ch:
+	case <-w.done:
+		return true
+	}
+	if listing.err != nil {
+		return !yield(nil, listing.err)
+	}
+
+	// Dispatch reads for subdirectories up front so they proceed
+	// concurrently while this directory's own entries are emitted.
+	subChans := make([]chan dirListing, len(listing.entries))
+	for i, entry := range listing.entries {
+		if w.shouldDescend(dir, entry) {
+			subChans[i] = w.fetch(filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	for i, entry := range listing.entries {
+		if !yield(&DirAndEntry{dir: dir, entry: entry}, nil) {
+			return true
+		}
+		if subChans[i] != nil {
+			subDir := filepath.Join(dir, entry.Name())
+			if w.walkOrdered(subDir, subChans[i], yield) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package readdiriter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestNewReadDirIterRecursiveParallel(t *testing.T) {
+	buildTree := func(t *testing.T) (dir string, wantDirs, wantFiles []string) {
+		t.Helper()
+		dir = tempDir(t)
+
+		inputDirs := []string{
+			"a",
+			filepath.Join("a", "b"),
+			filepath.Join("a", "b", "c"),
+			filepath.Join("a", "d"),
+		}
+		inputFiles := []string{
+			filepath.Join("a", "f1"),
+			filepath.Join("a", "f2"),
+			filepath.Join("a", "b", "c", "f2"),
+		}
+
+		for _, inputDir := range inputDirs {
+			dirPath := filepath.Join(dir, inputDir)
+			if err := os.Mkdir(dirPath, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			wantDirs = append(wantDirs, dirPath)
+		}
+		for _, inputFile := range inputFiles {
+			filePath := filepath.Join(dir, inputFile)
+			if err := os.WriteFile(filePath, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			wantFiles = append(wantFiles, filePath)
+		}
+		slices.Sort(wantDirs)
+		slices.Sort(wantFiles)
+		return dir, wantDirs, wantFiles
+	}
+
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ordered=%v", ordered), func(t *testing.T) {
+			for _, concurrency := range []int{0, 1, 4} {
+				t.Run(fmt.Sprintf("concurrency=%d", concurrency), func(t *testing.T) {
+					dir, wantDirs, wantFiles := buildTree(t)
+
+					openDir := func(name string) (ReadDirCloser, error) {
+						return os.Open(name)
+					}
+					opts := ParallelOptions{Concurrency: concurrency, Ordered: ordered}
+					var gotDirs, gotFiles []string
+					for de, err := range NewReadDirIterRecursiveParallel(dir, openDir, opts) {
+						if err != nil {
+							t.Fatal(err)
+						}
+						dePath := filepath.Join(de.Dir(), de.Entry().Name())
+						if de.Entry().IsDir() {
+							gotDirs = append(gotDirs, dePath)
+						} else {
+							gotFiles = append(gotFiles, dePath)
+						}
+					}
+					slices.Sort(gotDirs)
+					slices.Sort(gotFiles)
+
+					if !slices.Equal(gotDirs, wantDirs) {
+						t.Errorf("dirs mismatch,\n got=%v,\nwant=%v", gotDirs, wantDirs)
+					}
+					if !slices.Equal(gotFiles, wantFiles) {
+						t.Errorf("files mismatch,\n got=%v,\nwant=%v", gotFiles, wantFiles)
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("orderedIsParentBeforeChildren", func(t *testing.T) {
+		dir, _, _ := buildTree(t)
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		seen := make(map[string]bool)
+		for de, err := range NewReadDirIterRecursiveParallel(dir, openDir, ParallelOptions{Concurrency: 4, Ordered: true}) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(de.Dir(), de.Entry().Name())
+			if de.Dir() != dir && !seen[de.Dir()] {
+				t.Errorf("entry %s yielded before its parent directory %s", path, de.Dir())
+			}
+			seen[path] = true
+		}
+	})
+
+	t.Run("breakLoopDoesNotLeakGoroutines", func(t *testing.T) {
+		dir, _, _ := buildTree(t)
+
+		before := runtime.NumGoroutine()
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		for range NewReadDirIterRecursiveParallel(dir, openDir, ParallelOptions{Concurrency: 4}) {
+			break
+		}
+
+		after := runtime.NumGoroutine()
+		if after > before {
+			t.Errorf("goroutines leaked: before=%d, after=%d", before, after)
+		}
+	})
+
+	t.Run("openDirError", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		openDir := func(name string) (ReadDirCloser, error) {
+			return nil, wantErr
+		}
+		var gotErr error
+		for _, err := range NewReadDirIterRecursiveParallel("nonexistent", openDir, ParallelOptions{}) {
+			if err != nil {
+				gotErr = err
+			}
+		}
+		if !errors.Is(gotErr, wantErr) {
+			t.Errorf("got err=%v, want=%v", gotErr, wantErr)
+		}
+	})
+
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("descendPrunesSubtree/ordered=%v", ordered), func(t *testing.T) {
+			dir, _, _ := buildTree(t)
+
+			openDir := func(name string) (ReadDirCloser, error) {
+				return os.Open(name)
+			}
+			descend := func(dir string, entry fs.DirEntry) bool {
+				return entry.Name() != "b"
+			}
+			opts := ParallelOptions{Concurrency: 4, Ordered: ordered, Descend: descend}
+
+			var gotPaths []string
+			for de, err := range NewReadDirIterRecursiveParallel(dir, openDir, opts) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotPaths = append(gotPaths, filepath.Join(de.Dir(), de.Entry().Name()))
+			}
+			slices.Sort(gotPaths)
+
+			want := []string{
+				filepath.Join(dir, "a"),
+				filepath.Join(dir, "a", "b"),
+				filepath.Join(dir, "a", "d"),
+				filepath.Join(dir, "a", "f1"),
+				filepath.Join(dir, "a", "f2"),
+			}
+			slices.Sort(want)
+			if !slices.Equal(gotPaths, want) {
+				t.Errorf("gotPaths=%v, want=%v", gotPaths, want)
+			}
+		})
+	}
+}
+
+func BenchmarkNewReadDirIterRecursive_Serial(b *testing.B) {
+	dir := benchTreeDir(b)
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		for _, err := range NewReadDirIterRecursive(dir, openDir, 0, nil) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkNewReadDirIterRecursiveParallel(b *testing.B) {
+	dir := benchTreeDir(b)
+	openDir := func(name string) (ReadDirCloser, error) {
+		return os.Open(name)
+	}
+
+	for _, concurrency := range []int{2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			opts := ParallelOptions{Concurrency: concurrency}
+			b.ResetTimer()
+			for range b.N {
+				for _, err := range NewReadDirIterRecursiveParallel(dir, openDir, opts) {
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// benchTreeDir builds a tree with roughly 100k files spread across nested
+// directories, for comparing the serial and parallel walkers.
+func benchTreeDir(b *testing.B) string {
+	b.Helper()
+
+	const (
+		dirsPerLevel = 10
+		levels       = 2
+		filesPerLeaf = 1_000
+	)
+
+	dir := b.TempDir()
+
+	var makeLevel func(path string, depth int)
+	makeLevel = func(path string, depth int) {
+		if depth == levels {
+			for i := range filesPerLeaf {
+				name := filepath.Join(path, fmt.Sprintf("f%d", i))
+				if err := os.WriteFile(name, nil, 0o600); err != nil {
+					b.Fatal(err)
+				}
+			}
+			return
+		}
+		for i := range dirsPerLevel {
+			subDir := filepath.Join(path, fmt.Sprintf("d%d", i))
+			if err := os.Mkdir(subDir, 0o700); err != nil {
+				b.Fatal(err)
+			}
+			makeLevel(subDir, depth+1)
+		}
+	}
+	makeLevel(dir, 0)
+
+	return dir
+}
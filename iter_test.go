@@ -2,6 +2,7 @@ package readdiriter
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"testing"
@@ -332,3 +333,82 @@ func TestNewReadDirIter(t *testing.T) {
 		})
 	}
 }
+
+// TestNewReadDirIterSortedBatched tests the NewReadDirIterSortedBatched
+// function with various batch sizes, checking that the result is the same
+// lexically sorted order NewReadDirIterSorted would produce regardless of
+// how the underlying ReadDirer chunks its reads.
+func TestNewReadDirIterSortedBatched(t *testing.T) {
+	entries := []fs.DirEntry{
+		mockDirEntry{name: "c.txt"},
+		mockDirEntry{name: "a.txt"},
+		mockDirEntry{name: "e.txt"},
+		mockDirEntry{name: "b.txt"},
+		mockDirEntry{name: "d.txt"},
+	}
+	wantNames := []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"}
+
+	for _, batchSize := range []int{0, 1, 2, 3, len(entries), len(entries) + 1} {
+		t.Run(fmt.Sprintf("batchSize=%d", batchSize), func(t *testing.T) {
+			mockFile := &mockReadDirFile{entries: entries}
+
+			var gotNames []string
+			for entry, err := range NewReadDirIterSortedBatched(mockFile, batchSize) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				gotNames = append(gotNames, entry.Name())
+			}
+
+			if len(gotNames) != len(wantNames) {
+				t.Fatalf("got %d entries, want %d", len(gotNames), len(wantNames))
+			}
+			for i, name := range gotNames {
+				if name != wantNames[i] {
+					t.Errorf("entry at index %d mismatch. got=%s, want=%s", i, name, wantNames[i])
+				}
+			}
+		})
+	}
+
+	t.Run("breakStopsMergeEarly", func(t *testing.T) {
+		mockFile := &mockReadDirFile{entries: entries}
+
+		var gotNames []string
+		for entry, err := range NewReadDirIterSortedBatched(mockFile, 2) {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			gotNames = append(gotNames, entry.Name())
+			if len(gotNames) == 2 {
+				break
+			}
+		}
+
+		want := []string{"a.txt", "b.txt"}
+		if len(gotNames) != len(want) {
+			t.Fatalf("got %v, want %v", gotNames, want)
+		}
+		for i, name := range gotNames {
+			if name != want[i] {
+				t.Errorf("entry at index %d mismatch. got=%s, want=%s", i, name, want[i])
+			}
+		}
+	})
+
+	t.Run("propagatesReadDirError", func(t *testing.T) {
+		wantErr := errors.New("disk full")
+		mockFile := &mockReadDirFile{entries: entries, errOnCall: wantErr}
+
+		var gotErr error
+		for _, err := range NewReadDirIterSortedBatched(mockFile, 2) {
+			if err != nil {
+				gotErr = err
+				break
+			}
+		}
+		if !errors.Is(gotErr, wantErr) && (gotErr == nil || gotErr.Error() != wantErr.Error()) {
+			t.Errorf("got err=%v, want=%v", gotErr, wantErr)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package readdiriter
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// WalkDir walks the directory tree rooted at baseDir, calling fn for each
+// file or directory entry found, including entries directly inside baseDir.
+//
+// WalkDir mirrors the contract of filepath.WalkDir and fs.WalkDir: fn is
+// called with the full path and the fs.DirEntry for each visited entry. If
+// fn returns fs.SkipDir, WalkDir skips the current directory (if the entry
+// is a directory) or the remaining entries in the directory containing the
+// entry (if it is not). fn is also called with a non-nil err when a
+// directory cannot be opened or read; fs.SkipDir returned from one of those
+// calls likewise skips the rest of that directory rather than being
+// returned as an error. If fn returns fs.SkipAll, WalkDir stops the walk
+// entirely and returns nil. Any other non-nil error returned by fn stops the
+// walk and is returned by WalkDir.
+//
+// Unlike filepath.WalkDir, WalkDir does not call fn for baseDir itself,
+// since no fs.DirEntry is available for baseDir without an extra stat call;
+// the walk begins with baseDir's children, the same starting point as
+// NewReadDirIterRecursive.
+//
+// The n parameter follows the semantics of fs.ReadDirFile:
+// https://pkg.go.dev/io/fs@latest#ReadDirFile.
+//
+// Note: The directory entries are not in lexical order in each directory.
+func WalkDir(baseDir string, openDir OpenReadDirCloserFunc, n int, fn fs.WalkDirFunc) error {
+	err := walkDirFn(baseDir, openDir, n, fn)
+	if err == fs.SkipAll {
+		err = nil
+	}
+	return err
+}
+
+// WalkDirSorted is the lexically sorted variant of WalkDir.
+//
+// Note: The directory entries are in lexical order in each directory.
+func WalkDirSorted(baseDir string, openDir OpenReadDirCloserFunc, fn fs.WalkDirFunc) error {
+	err := walkDirSortedFn(baseDir, openDir, fn)
+	if err == fs.SkipAll {
+		err = nil
+	}
+	return err
+}
+
+// WalkDirSortedBatched is WalkDirSorted, but reads each directory in chunks
+// of at most batchSize entries via NewReadDirIterSortedBatched instead of
+// with a single ReadDir(0) call. This does not lower peak memory: producing
+// a directory's entries in order requires having read all of them first, so
+// a directory's own entries are buffered in full either way; it only
+// replaces one large read with several reads of at most batchSize entries.
+// batchSize <= 0 behaves like WalkDirSorted.
+//
+// Note: The directory entries are in lexical order in each directory.
+func WalkDirSortedBatched(baseDir string, openDir OpenReadDirCloserFunc, batchSize int, fn fs.WalkDirFunc) error {
+	err := walkDirSortedBatchedFn(baseDir, openDir, batchSize, fn)
+	if err == fs.SkipAll {
+		err = nil
+	}
+	return err
+}
+
+func walkDirFn(dir string, openDir OpenReadDirCloserFunc, n int, fn fs.WalkDirFunc) error {
+	dirFile, err := openDir(dir)
+	if err != nil {
+		if ferr := fn(dir, nil, err); ferr != nil {
+			if ferr == fs.SkipDir {
+				return nil
+			}
+			return ferr
+		}
+		return nil
+	}
+	defer dirFile.Close()
+
+	for entry, err := range NewReadDirIter(dirFile, n) {
+		if err != nil {
+			if ferr := fn(dir, nil, err); ferr != nil {
+				if ferr == fs.SkipDir {
+					// fn asked to skip the rest of this directory, not to
+					// stop the walk with this error.
+					return nil
+				}
+				return ferr
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := fn(path, entry, nil); err != nil {
+			if err == fs.SkipDir {
+				if entry.IsDir() {
+					continue
+				}
+				// entry is not a directory: skip the remaining entries in dir.
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if err := walkDirFn(path, openDir, n, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkDirSortedFn(dir string, openDir OpenReadDirCloserFunc, fn fs.WalkDirFunc) error {
+	dirFile, err := openDir(dir)
+	if err != nil {
+		if ferr := fn(dir, nil, err); ferr != nil {
+			if ferr == fs.SkipDir {
+				return nil
+			}
+			return ferr
+		}
+		return nil
+	}
+	defer dirFile.Close()
+
+	for entry, err := range NewReadDirIterSorted(dirFile) {
+		if err != nil {
+			if ferr := fn(dir, nil, err); ferr != nil {
+				if ferr == fs.SkipDir {
+					// fn asked to skip the rest of this directory, not to
+					// stop the walk with this error.
+					return nil
+				}
+				return ferr
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := fn(path, entry, nil); err != nil {
+			if err == fs.SkipDir {
+				if entry.IsDir() {
+					continue
+				}
+				// entry is not a directory: skip the remaining entries in dir.
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if err := walkDirSortedFn(path, openDir, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkDirSortedBatchedFn(dir string, openDir OpenReadDirCloserFunc, batchSize int, fn fs.WalkDirFunc) error {
+	dirFile, err := openDir(dir)
+	if err != nil {
+		if ferr := fn(dir, nil, err); ferr != nil {
+			if ferr == fs.SkipDir {
+				return nil
+			}
+			return ferr
+		}
+		return nil
+	}
+	defer dirFile.Close()
+
+	for entry, err := range NewReadDirIterSortedBatched(dirFile, batchSize) {
+		if err != nil {
+			if ferr := fn(dir, nil, err); ferr != nil {
+				if ferr == fs.SkipDir {
+					// fn asked to skip the rest of this directory, not to
+					// stop the walk with this error.
+					return nil
+				}
+				return ferr
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := fn(path, entry, nil); err != nil {
+			if err == fs.SkipDir {
+				if entry.IsDir() {
+					continue
+				}
+				// entry is not a directory: skip the remaining entries in dir.
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if err := walkDirSortedBatchedFn(path, openDir, batchSize, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package readdiriter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// erroringReadDirCloser is a ReadDirCloser whose ReadDir always fails,
+// simulating a directory that opens successfully but cannot be read.
+type erroringReadDirCloser struct {
+	err error
+}
+
+func (r *erroringReadDirCloser) ReadDir(n int) ([]fs.DirEntry, error) {
+	return nil, r.err
+}
+
+func (r *erroringReadDirCloser) Close() error {
+	return nil
+}
+
+func TestWalkDir(t *testing.T) {
+	t.Run("noSkip", func(t *testing.T) {
+		dir := tempDir(t)
+
+		inputDirs := []string{
+			"a",
+			filepath.Join("a", "b"),
+			filepath.Join("a", "b", "c"),
+			filepath.Join("a", "d"),
+		}
+		inputFiles := []string{
+			filepath.Join("a", "f1"),
+			filepath.Join("a", "f2"),
+			filepath.Join("a", "b", "c", "f2"),
+		}
+
+		wantDirs := make([]string, len(inputDirs))
+		for i, inputDir := range inputDirs {
+			dirPath := filepath.Join(dir, inputDir)
+			if err := os.Mkdir(dirPath, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			wantDirs[i] = dirPath
+		}
+		slices.Sort(wantDirs)
+
+		wantFiles := make([]string, len(inputFiles))
+		for i, inputFile := range inputFiles {
+			filePath := filepath.Join(dir, inputFile)
+			if err := os.WriteFile(filePath, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			wantFiles[i] = filePath
+		}
+		slices.Sort(wantFiles)
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		var gotDirs, gotFiles []string
+		err := WalkDir(dir, openDir, 0, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				gotDirs = append(gotDirs, path)
+			} else {
+				gotFiles = append(gotFiles, path)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(gotDirs)
+		slices.Sort(gotFiles)
+
+		if !slices.Equal(gotDirs, wantDirs) {
+			t.Errorf("dirs mismatch,\n got=%v,\nwant=%v", gotDirs, wantDirs)
+		}
+		if !slices.Equal(gotFiles, wantFiles) {
+			t.Errorf("files mismatch,\n got=%v,\nwant=%v", gotFiles, wantFiles)
+		}
+	})
+
+	t.Run("skipDirEntry", func(t *testing.T) {
+		dir := tempDir(t)
+
+		inputDirs := []string{
+			"a",
+			filepath.Join("a", "b"),
+			filepath.Join("a", "b", "c"),
+			filepath.Join("a", "d"),
+		}
+
+		for _, inputDir := range inputDirs {
+			dirPath := filepath.Join(dir, inputDir)
+			if err := os.Mkdir(dirPath, 0o700); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		wantDirs := []string{
+			filepath.Join(dir, "a"),
+			filepath.Join(dir, "a", "d"),
+		}
+		slices.Sort(wantDirs)
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		var gotDirs []string
+		err := WalkDirSorted(dir, openDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if path == filepath.Join(dir, "a", "b") {
+				return fs.SkipDir
+			}
+			gotDirs = append(gotDirs, path)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(gotDirs)
+
+		if !slices.Equal(gotDirs, wantDirs) {
+			t.Errorf("dirs mismatch,\n got=%v,\nwant=%v", gotDirs, wantDirs)
+		}
+	})
+
+	t.Run("skipDirOnFileSkipsRestOfDir", func(t *testing.T) {
+		dir := tempDir(t)
+
+		if err := os.WriteFile(filepath.Join(dir, "a1"), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "a2"), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "a3"), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		var gotFiles []string
+		err := WalkDirSorted(dir, openDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == filepath.Join(dir, "a2") {
+				return fs.SkipDir
+			}
+			gotFiles = append(gotFiles, path)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantFiles := []string{filepath.Join(dir, "a1")}
+		if !slices.Equal(gotFiles, wantFiles) {
+			t.Errorf("files mismatch,\n got=%v,\nwant=%v", gotFiles, wantFiles)
+		}
+	})
+
+	t.Run("skipAll", func(t *testing.T) {
+		dir := tempDir(t)
+
+		inputDirs := []string{"a", "b", "c"}
+		for _, inputDir := range inputDirs {
+			if err := os.Mkdir(filepath.Join(dir, inputDir), 0o700); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		var gotDirs []string
+		err := WalkDirSorted(dir, openDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == filepath.Join(dir, "b") {
+				return fs.SkipAll
+			}
+			gotDirs = append(gotDirs, path)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wantDirs := []string{filepath.Join(dir, "a")}
+		if !slices.Equal(gotDirs, wantDirs) {
+			t.Errorf("dirs mismatch,\n got=%v,\nwant=%v", gotDirs, wantDirs)
+		}
+	})
+
+	t.Run("sortedBatchedMatchesSorted", func(t *testing.T) {
+		dir := tempDir(t)
+
+		inputDirs := []string{"a", filepath.Join("a", "b")}
+		for _, inputDir := range inputDirs {
+			if err := os.Mkdir(filepath.Join(dir, inputDir), 0o700); err != nil {
+				t.Fatal(err)
+			}
+		}
+		inputFiles := []string{
+			filepath.Join("a", "f1"),
+			filepath.Join("a", "f2"),
+			filepath.Join("a", "b", "f3"),
+		}
+		for _, inputFile := range inputFiles {
+			if err := os.WriteFile(filepath.Join(dir, inputFile), nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+
+		var wantPaths []string
+		if err := WalkDirSorted(dir, openDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			wantPaths = append(wantPaths, path)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, batchSize := range []int{0, 1, 2} {
+			t.Run(fmt.Sprintf("batchSize=%d", batchSize), func(t *testing.T) {
+				var gotPaths []string
+				err := WalkDirSortedBatched(dir, openDir, batchSize, func(path string, d fs.DirEntry, err error) error {
+					if err != nil {
+						return err
+					}
+					gotPaths = append(gotPaths, path)
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(gotPaths, wantPaths) {
+					t.Errorf("gotPaths=%v, want=%v", gotPaths, wantPaths)
+				}
+			})
+		}
+	})
+
+	t.Run("errorStopsWalk", func(t *testing.T) {
+		dir := tempDir(t)
+		if err := os.Mkdir(filepath.Join(dir, "a"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		openDir := func(name string) (ReadDirCloser, error) {
+			return os.Open(name)
+		}
+		wantErr := errors.New("boom")
+		err := WalkDir(dir, openDir, 0, func(path string, d fs.DirEntry, err error) error {
+			return wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("got err=%v, want=%v", err, wantErr)
+		}
+	})
+
+	t.Run("skipDirOnReadDirErrorIsNotReturned", func(t *testing.T) {
+		openDir := func(name string) (ReadDirCloser, error) {
+			return &erroringReadDirCloser{err: errors.New("boom")}, nil
+		}
+		err := WalkDir("dir", openDir, 0, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return fs.SkipDir
+			}
+			return errors.New("unexpected call for " + path)
+		})
+		if err != nil {
+			t.Errorf("got err=%v, want nil", err)
+		}
+	})
+
+	t.Run("skipDirOnOpenDirErrorIsNotReturned", func(t *testing.T) {
+		openDir := func(name string) (ReadDirCloser, error) {
+			return nil, errors.New("boom")
+		}
+		err := WalkDir("nonexistent", openDir, 0, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return fs.SkipDir
+			}
+			return errors.New("unexpected call for " + path)
+		})
+		if err != nil {
+			t.Errorf("got err=%v, want nil", err)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+//go:build aix
+
+package unixdirents
+
+import (
+	"encoding/binary"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	direntReclenOff  = int(unsafe.Offsetof(unix.Dirent{}.Reclen))
+	direntReclenSize = int(unsafe.Sizeof(unix.Dirent{}.Reclen))
+	direntNameOff    = int(unsafe.Offsetof(unix.Dirent{}.Name))
+)
+
+func direntIno(rec []byte) uint64 {
+	const (
+		off  = int(unsafe.Offsetof(unix.Dirent{}.Ino))
+		size = int(unsafe.Sizeof(unix.Dirent{}.Ino))
+	)
+	if size == 8 {
+		return binary.NativeEndian.Uint64(rec[off:])
+	}
+	return uint64(binary.NativeEndian.Uint32(rec[off:]))
+}
+
+// direntType always reports DTypeUnknown: AIX dirents have no d_type field.
+func direntType(rec []byte) DType {
+	return DTypeUnknown
+}
@@ -0,0 +1,34 @@
+//go:build netbsd
+
+package unixdirents
+
+import (
+	"encoding/binary"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	direntReclenOff  = int(unsafe.Offsetof(unix.Dirent{}.Reclen))
+	direntReclenSize = int(unsafe.Sizeof(unix.Dirent{}.Reclen))
+	direntNameOff    = int(unsafe.Offsetof(unix.Dirent{}.Name))
+)
+
+// direntIno reads the file serial number, named Fileno rather than Ino in
+// NetBSD's unix.Dirent.
+func direntIno(rec []byte) uint64 {
+	const (
+		off  = int(unsafe.Offsetof(unix.Dirent{}.Fileno))
+		size = int(unsafe.Sizeof(unix.Dirent{}.Fileno))
+	)
+	if size == 8 {
+		return binary.NativeEndian.Uint64(rec[off:])
+	}
+	return uint64(binary.NativeEndian.Uint32(rec[off:]))
+}
+
+func direntType(rec []byte) DType {
+	const off = int(unsafe.Offsetof(unix.Dirent{}.Type))
+	return DType(rec[off])
+}
@@ -0,0 +1,79 @@
+//go:build windows
+
+package unixdirents
+
+import (
+	"iter"
+	"path/filepath"
+	"syscall"
+)
+
+// fileAttributeDevice is FILE_ATTRIBUTE_DEVICE, which the syscall package
+// does not define.
+const fileAttributeDevice = 0x40
+
+// Dirents returns an iterator over directory entries within the directory
+// at path, built on FindFirstFile/FindNextFile rather than the raw
+// getdents-style buffer used on Unix, since Windows exposes no equivalent
+// file descriptor based interface.
+//
+// Ino is always 0: FindFirstFile/FindNextFile do not expose a file ID, and
+// obtaining one requires an extra per-entry open-and-query that this
+// function intentionally avoids.
+//
+// Note: The directory entries are not in lexical order.
+func Dirents(path string) iter.Seq2[DirentInfo, error] {
+	return func(yield func(DirentInfo, error) bool) {
+		pattern, err := syscall.UTF16PtrFromString(filepath.Join(path, "*"))
+		if err != nil {
+			yield(DirentInfo{}, err)
+			return
+		}
+
+		var data syscall.Win32finddata
+		handle, err := syscall.FindFirstFile(pattern, &data)
+		if err != nil {
+			yield(DirentInfo{}, err)
+			return
+		}
+		defer syscall.FindClose(handle)
+
+		for {
+			name := syscall.UTF16ToString(data.FileName[:])
+			if name != "." && name != ".." {
+				di := DirentInfo{
+					Type: dTypeFromFileAttributes(data.FileAttributes),
+					Name: []byte(name),
+				}
+				if !yield(di, nil) {
+					return
+				}
+			}
+
+			if err := syscall.FindNextFile(handle, &data); err != nil {
+				if err == syscall.ERROR_NO_MORE_FILES {
+					return
+				}
+				yield(DirentInfo{}, err)
+				return
+			}
+		}
+	}
+}
+
+// dTypeFromFileAttributes maps Win32 file attributes to the closest DType,
+// falling back to DTypeUnknown where Windows exposes no equivalent concept
+// (e.g. named pipes and sockets are not regular directory entries on
+// Windows).
+func dTypeFromFileAttributes(attrs uint32) DType {
+	switch {
+	case attrs&syscall.FILE_ATTRIBUTE_REPARSE_POINT != 0:
+		return DTypeSymlink
+	case attrs&syscall.FILE_ATTRIBUTE_DIRECTORY != 0:
+		return DTypeDir
+	case attrs&fileAttributeDevice != 0:
+		return DTypeUnknown
+	default:
+		return DTypeRegularFile
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package unixdirents
+
+import "log"
+
+func ExampleDirents() {
+	for de, err := range Dirents(".") {
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("directory entry=%+v", de)
+	}
+}
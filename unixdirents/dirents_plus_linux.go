@@ -0,0 +1,228 @@
+//go:build linux
+
+package unixdirents
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// PlusOptions configures DirentsPlus.
+type PlusOptions struct {
+	// Mask selects which statx fields to retrieve, using the unix.STATX_*
+	// bits (for example unix.STATX_MODE|unix.STATX_MTIME). Callers only pay
+	// the cost of fields they request; fields outside Mask may be left
+	// zero in the returned DirentPlus.
+	Mask uint32
+
+	// Concurrency is the number of statx (or fstatat fallback) calls
+	// allowed to run at once while the getdents producer keeps filling
+	// buf. Values <= 0 are treated as 1, which behaves like a serial walk.
+	Concurrency int
+}
+
+// DirentPlus is a directory entry augmented with the subset of stat(2)
+// fields requested through PlusOptions.Mask.
+type DirentPlus struct {
+	// Ino is the 64-bit inode number.
+	Ino uint64
+
+	// Type is the file type.
+	Type DType
+
+	// Name is the filename.
+	//
+	// Unlike DirentInfo.Name, Name is a copy: the statx call behind it runs
+	// in the background and can outlive the next unix.ReadDirent into buf,
+	// so it cannot alias the caller-provided buffer.
+	Name string
+
+	// Mode holds the file type and permission bits, as returned by statx
+	// or, on kernels without statx, fstatat.
+	Mode uint16
+
+	// Uid is the owner user ID.
+	Uid uint32
+
+	// Gid is the owner group ID.
+	Gid uint32
+
+	// Size is the file size in bytes.
+	Size uint64
+
+	// Atime is the last access time.
+	Atime time.Time
+
+	// Mtime is the last modification time.
+	Mtime time.Time
+
+	// Ctime is the last status change time.
+	Ctime time.Time
+
+	// Btime is the creation time. It is the zero Time if STATX_BTIME was
+	// not requested in Mask, or if the filesystem does not support it even
+	// when requested: check Mask&unix.STATX_BTIME before relying on it.
+	Btime time.Time
+}
+
+// plusResult is the outcome of one entry's background statx call.
+type plusResult struct {
+	dp  DirentPlus
+	err error
+}
+
+// statxSupported tracks whether the running kernel supports statx(2). It
+// starts optimistic and is cleared the first time statx reports ENOSYS, so
+// that a kernel without statx pays the ENOSYS probe only once rather than
+// once per entry.
+var statxSupported atomic.Bool
+
+func init() {
+	statxSupported.Store(true)
+}
+
+// DirentsPlus returns an iterator over directory entries within the
+// specified file descriptor fd, each augmented with the stat(2) fields
+// selected by opts.Mask.
+//
+// For every entry found by the underlying getdents scan, DirentsPlus issues
+// a unix.Statx(fd, name, AT_SYMLINK_NOFOLLOW, opts.Mask, ...) call to fetch
+// the requested fields without following symlinks. On kernels that return
+// ENOSYS for statx, DirentsPlus falls back to fstatat for the rest of the
+// call and every subsequent call in the process, and Mode/Uid/Gid/Size/
+// Atime/Mtime/Ctime are populated regardless of Mask; Btime is left zero
+// since fstatat exposes no creation time.
+//
+// opts.Concurrency bounds how many of these calls run at once; they are
+// pipelined so that statx latency on high-latency filesystems (NFS, FUSE)
+// is hidden behind the getdents scan that keeps refilling buf in the
+// background, while entries are still yielded in the order getdents
+// produced them.
+//
+// Note: The directory entries are not in lexical order.
+func DirentsPlus(fd int, buf []byte, opts PlusOptions) iter.Seq2[DirentPlus, error] {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return func(yield func(DirentPlus, error) bool) {
+		sem := make(chan struct{}, concurrency)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		// Deferred in this order so close runs before Wait: closing done
+		// first lets any producer or worker still blocked on a send or the
+		// semaphore unblock via their <-done case, so Wait actually
+		// returns.
+		defer wg.Wait()
+		defer close(done)
+
+		queue := make(chan chan plusResult, concurrency)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(queue)
+			for info, err := range Dirents(fd, buf) {
+				ch := make(chan plusResult, 1)
+				select {
+				case queue <- ch:
+				case <-done:
+					return
+				}
+
+				if err != nil {
+					ch <- plusResult{err: err}
+					return
+				}
+
+				name := string(info.Name)
+				wg.Add(1)
+				go func(ino uint64, typ DType, name string) {
+					defer wg.Done()
+					select {
+					case sem <- struct{}{}:
+					case <-done:
+						return
+					}
+					defer func() { <-sem }()
+
+					dp, err := statxEntry(fd, ino, typ, name, opts.Mask)
+					select {
+					case ch <- plusResult{dp: dp, err: err}:
+					case <-done:
+					}
+				}(info.Ino, info.Type, name)
+			}
+		}()
+
+		for ch := range queue {
+			var res plusResult
+			select {
+			case res = <-ch:
+			case <-done:
+				return
+			}
+			if !yield(res.dp, res.err) {
+				return
+			}
+		}
+	}
+}
+
+// statxEntry retrieves the requested stat fields for name within directory
+// fd, without following symlinks. It uses statx when available and falls
+// back to fstatat once statx has been observed to return ENOSYS.
+func statxEntry(fd int, ino uint64, typ DType, name string, mask uint32) (DirentPlus, error) {
+	dp := DirentPlus{Ino: ino, Type: typ, Name: name}
+
+	if statxSupported.Load() {
+		var stat unix.Statx_t
+		err := unix.Statx(fd, name, unix.AT_SYMLINK_NOFOLLOW, int(mask), &stat)
+		if err == nil {
+			fillFromStatx(&dp, &stat)
+			return dp, nil
+		}
+		if err != unix.ENOSYS {
+			return dp, err
+		}
+		statxSupported.Store(false)
+	}
+
+	var stat unix.Stat_t
+	if err := unix.Fstatat(fd, name, &stat, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		return dp, err
+	}
+	fillFromStat(&dp, &stat)
+	return dp, nil
+}
+
+func fillFromStatx(dp *DirentPlus, stat *unix.Statx_t) {
+	dp.Mode = stat.Mode
+	dp.Uid = stat.Uid
+	dp.Gid = stat.Gid
+	dp.Size = stat.Size
+	dp.Atime = statxTimestampToTime(stat.Atime)
+	dp.Mtime = statxTimestampToTime(stat.Mtime)
+	dp.Ctime = statxTimestampToTime(stat.Ctime)
+	if stat.Mask&unix.STATX_BTIME != 0 {
+		dp.Btime = statxTimestampToTime(stat.Btime)
+	}
+}
+
+func statxTimestampToTime(ts unix.StatxTimestamp) time.Time {
+	return time.Unix(ts.Sec, int64(ts.Nsec))
+}
+
+func fillFromStat(dp *DirentPlus, stat *unix.Stat_t) {
+	dp.Mode = uint16(stat.Mode)
+	dp.Uid = stat.Uid
+	dp.Gid = stat.Gid
+	dp.Size = uint64(stat.Size)
+	dp.Atime = time.Unix(stat.Atim.Sec, stat.Atim.Nsec)
+	dp.Mtime = time.Unix(stat.Mtim.Sec, stat.Mtim.Nsec)
+	dp.Ctime = time.Unix(stat.Ctim.Sec, stat.Ctim.Nsec)
+}
@@ -0,0 +1,124 @@
+//go:build linux
+
+package unixdirents
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestDirentsPlus(t *testing.T) {
+	openDir := func(t *testing.T, dir string) *os.File {
+		f, err := os.Open(dir)
+		if err != nil {
+			t.Fatalf("open dir: %s, %s", dir, err)
+		}
+		t.Cleanup(func() {
+			if err := f.Close(); err != nil {
+				t.Fatalf("close dir: %s, %s", dir, err)
+			}
+		})
+		return f
+	}
+
+	allDirentsPlus := func(t *testing.T, dir string, opts PlusOptions) map[string]DirentPlus {
+		f := openDir(t, dir)
+
+		got := make(map[string]DirentPlus)
+		var buf [4096]byte
+		for dp, err := range DirentsPlus(int(f.Fd()), buf[:], opts) {
+			if err != nil {
+				t.Fatalf("error in DirentsPlus: %s", err)
+			}
+			got[dp.Name] = dp
+		}
+		return got
+	}
+
+	t.Run("sizeAndMode", func(t *testing.T) {
+		dir := t.TempDir()
+		content := []byte("hello, world")
+		if err := os.WriteFile(dir+"/file", content, 0o640); err != nil {
+			t.Fatalf("write file: %s", err)
+		}
+
+		got := allDirentsPlus(t, dir, PlusOptions{
+			Mask:        unix.STATX_SIZE | unix.STATX_MODE,
+			Concurrency: 4,
+		})
+		dp, ok := got["file"]
+		if !ok {
+			t.Fatalf("missing entry for file, got %v", got)
+		}
+		if dp.Type != DTypeRegularFile {
+			t.Errorf("Type = %v, want %v", dp.Type, DTypeRegularFile)
+		}
+		if dp.Size != uint64(len(content)) {
+			t.Errorf("Size = %d, want %d", dp.Size, len(content))
+		}
+		if dp.Mode&0o777 != 0o640 {
+			t.Errorf("Mode = %o, want permission bits 0640", dp.Mode)
+		}
+	})
+
+	t.Run("mtimeMatchesStat", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(dir+"/file", nil, 0o600); err != nil {
+			t.Fatalf("write file: %s", err)
+		}
+		fi, err := os.Stat(dir + "/file")
+		if err != nil {
+			t.Fatalf("stat file: %s", err)
+		}
+
+		got := allDirentsPlus(t, dir, PlusOptions{Mask: unix.STATX_MTIME})
+		dp, ok := got["file"]
+		if !ok {
+			t.Fatalf("missing entry for file, got %v", got)
+		}
+		if !dp.Mtime.Truncate(time.Second).Equal(fi.ModTime().Truncate(time.Second)) {
+			t.Errorf("Mtime = %v, want %v", dp.Mtime, fi.ModTime())
+		}
+	})
+
+	t.Run("manyEntriesWithConcurrency", func(t *testing.T) {
+		dir := t.TempDir()
+		const n = 200
+		for i := range n {
+			if err := os.WriteFile(dir+"/"+string(rune('a'+i%26))+string(rune('0'+i/26)), nil, 0o600); err != nil {
+				t.Fatalf("write file %d: %s", i, err)
+			}
+		}
+
+		got := allDirentsPlus(t, dir, PlusOptions{Mask: unix.STATX_MODE, Concurrency: 8})
+		if len(got) != n {
+			t.Errorf("got %d entries, want %d", len(got), n)
+		}
+	})
+
+	t.Run("emptyDir", func(t *testing.T) {
+		dir := t.TempDir()
+		got := allDirentsPlus(t, dir, PlusOptions{Mask: unix.STATX_MODE})
+		if len(got) != 0 {
+			t.Errorf("got %d entries, want 0", len(got))
+		}
+	})
+
+	t.Run("breakLoopDoesNotDeadlock", func(t *testing.T) {
+		dir := t.TempDir()
+		for i := range 50 {
+			if err := os.WriteFile(dir+"/"+string(rune('a'+i%26))+string(rune('0'+i/26)), nil, 0o600); err != nil {
+				t.Fatalf("write file %d: %s", i, err)
+			}
+		}
+		f := openDir(t, dir)
+
+		var buf [4096]byte
+		for range DirentsPlus(int(f.Fd()), buf[:], PlusOptions{Mask: unix.STATX_MODE, Concurrency: 4}) {
+			break
+		}
+	})
+}
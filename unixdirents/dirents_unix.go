@@ -0,0 +1,96 @@
+//go:build unix
+
+package unixdirents
+
+import (
+	"bytes"
+	"encoding/binary"
+	"iter"
+
+	"golang.org/x/sys/unix"
+)
+
+// Dirents returns an iterator over directory entries within the specified file
+// descriptor fd.
+// The provided buffer buf is used for reading directory data.
+//
+// Note: The directory entries are not in lexical order.
+func Dirents(fd int, buf []byte) iter.Seq2[DirentInfo, error] {
+	return func(yield func(DirentInfo, error) bool) {
+		for {
+			n, err := unix.ReadDirent(fd, buf)
+			if err != nil {
+				yield(DirentInfo{}, err)
+				return
+			}
+			if n == 0 {
+				return
+			}
+
+			for de := range direntsInBuf(buf[:n]) {
+				if !yield(de, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// These declarations only compile if direntReclenSize, computed per-GOOS in
+// the dirent_*.go files from unsafe.Sizeof(unix.Dirent{}.Reclen), is
+// exactly 2: one array bound goes negative if direntReclenSize > 2, the
+// other if direntReclenSize < 2. direntsInBuf reads Reclen with
+// binary.NativeEndian.Uint16, which assumes exactly that size; a platform
+// whose Dirent.Reclen isn't a uint16 must fail here at compile time rather
+// than silently misparse the buffer at run time.
+var (
+	_ [direntReclenSize - 2]struct{}
+	_ [2 - direntReclenSize]struct{}
+)
+
+// direntsInBuf returns an iterator over directory entries found within the
+// given buffer.
+// This function is an internal helper.
+//
+// The byte offsets used to pick fields out of each record come from the
+// per-GOOS dirent_*.go files, which derive them from the platform's
+// unix.Dirent layout via unsafe.Offsetof/Sizeof rather than hardcoding them,
+// so this function itself has no platform-specific assumptions.
+func direntsInBuf(buf []byte) iter.Seq[DirentInfo] {
+	return func(yield func(DirentInfo) bool) {
+		for len(buf) >= direntReclenOff+direntReclenSize {
+			reclen := binary.NativeEndian.Uint16(buf[direntReclenOff:])
+			if reclen == 0 || int(reclen) > len(buf) {
+				return
+			}
+			rec := buf[:reclen]
+			buf = buf[reclen:]
+
+			ino := direntIno(rec)
+			if ino == 0 { // File absent in directory.
+				continue
+			}
+
+			dType := direntType(rec)
+
+			name := rec[direntNameOff:]
+			if i := bytes.IndexByte(name, byte('\x00')); i != -1 {
+				name = name[:i]
+			}
+
+			// Check for useless names before allocating a string.
+			if bytes.Equal(name, []byte(".")) || bytes.Equal(name, []byte("..")) {
+				continue
+			}
+
+			di := DirentInfo{
+				Ino:  ino,
+				Type: dType,
+				Name: name,
+			}
+			if !yield(di) {
+				return
+			}
+		}
+	}
+}